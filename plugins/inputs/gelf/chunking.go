@@ -0,0 +1,155 @@
+package gelf
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// GELF chunked messages are prefixed with a 2 byte magic number, followed
+// by an 8 byte message ID, a 1 byte sequence number and a 1 byte sequence
+// count. See http://docs.graylog.org/en/latest/pages/gelf.html#chunking
+const (
+	gelfChunkMagic0    = 0x1e
+	gelfChunkMagic1    = 0x0f
+	gelfChunkHeaderLen = 12
+	gelfMaxChunks      = 128
+)
+
+// pendingMessage holds the chunks received so far for a single message ID.
+type pendingMessage struct {
+	chunks    [][]byte
+	total     int
+	received  int
+	size      int
+	firstSeen time.Time
+}
+
+// chunkBuffer reassembles chunked GELF UDP messages, keyed by the 8-byte
+// message ID that every chunk of a message shares. Messages that never
+// receive all of their chunks are dropped after timeout.
+type chunkBuffer struct {
+	timeout        time.Duration
+	maxMessageSize int
+
+	mu      sync.Mutex
+	pending map[string]*pendingMessage
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newChunkBuffer(timeout time.Duration, maxMessageSize int) *chunkBuffer {
+	return &chunkBuffer{
+		timeout:        timeout,
+		maxMessageSize: maxMessageSize,
+		pending:        make(map[string]*pendingMessage),
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+}
+
+// Start launches the background goroutine that drops incomplete messages
+// once they have been pending longer than timeout.
+func (b *chunkBuffer) Start() {
+	period := b.timeout / 5
+	if period <= 0 {
+		period = time.Millisecond
+	}
+
+	go func() {
+		defer close(b.done)
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				b.purgeExpired()
+			case <-b.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the purge goroutine and waits for it to exit.
+func (b *chunkBuffer) Stop() {
+	close(b.stop)
+	<-b.done
+}
+
+func (b *chunkBuffer) purgeExpired() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	for id, msg := range b.pending {
+		if now.Sub(msg.firstSeen) > b.timeout {
+			delete(b.pending, id)
+		}
+	}
+}
+
+// Add feeds a single UDP datagram into the buffer. If data is not a GELF
+// chunk, it is returned unchanged (ready for decompression/parsing). If it
+// is a chunk, Add returns the reassembled message once every chunk for its
+// message ID has arrived, or nil while reassembly is still pending.
+func (b *chunkBuffer) Add(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != gelfChunkMagic0 || data[1] != gelfChunkMagic1 {
+		return data, nil
+	}
+
+	if len(data) < gelfChunkHeaderLen {
+		return nil, fmt.Errorf("short GELF chunk header (%d bytes)", len(data))
+	}
+
+	id := string(data[2:10])
+	seq := int(data[10])
+	count := int(data[11])
+	payload := data[gelfChunkHeaderLen:]
+
+	if count <= 0 || count > gelfMaxChunks {
+		return nil, fmt.Errorf("invalid GELF chunk count %d", count)
+	}
+	if seq >= count {
+		return nil, fmt.Errorf("invalid GELF chunk sequence %d of %d", seq, count)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	msg, ok := b.pending[id]
+	if !ok {
+		msg = &pendingMessage{
+			chunks:    make([][]byte, count),
+			total:     count,
+			firstSeen: time.Now(),
+		}
+		b.pending[id] = msg
+	} else if msg.total != count {
+		delete(b.pending, id)
+		return nil, fmt.Errorf("GELF chunk count mismatch for message %x", data[2:10])
+	}
+
+	if msg.chunks[seq] != nil {
+		// Duplicate chunk (e.g. re-sent by the client); keep the copy we
+		// already have and ignore this one.
+		return nil, nil
+	}
+
+	msg.chunks[seq] = payload
+	msg.received++
+	msg.size += len(payload)
+	if b.maxMessageSize > 0 && msg.size > b.maxMessageSize {
+		delete(b.pending, id)
+		return nil, fmt.Errorf("GELF message %x exceeds max_message_size", data[2:10])
+	}
+
+	if msg.received < msg.total {
+		return nil, nil
+	}
+
+	delete(b.pending, id)
+	return bytes.Join(msg.chunks, nil), nil
+}
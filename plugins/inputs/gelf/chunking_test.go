@@ -0,0 +1,96 @@
+package gelf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildChunk(id [8]byte, seq, count byte, payload []byte) []byte {
+	buf := make([]byte, 0, gelfChunkHeaderLen+len(payload))
+	buf = append(buf, gelfChunkMagic0, gelfChunkMagic1)
+	buf = append(buf, id[:]...)
+	buf = append(buf, seq, count)
+	buf = append(buf, payload...)
+	return buf
+}
+
+func TestChunkBufferReassemblesInOrder(t *testing.T) {
+	id := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+	b := newChunkBuffer(time.Second, 0)
+
+	got, err := b.Add(buildChunk(id, 0, 2, []byte("hello ")))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = b.Add(buildChunk(id, 1, 2, []byte("world")))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello world"), got)
+}
+
+func TestChunkBufferReassemblesOutOfOrder(t *testing.T) {
+	id := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+	b := newChunkBuffer(time.Second, 0)
+
+	got, err := b.Add(buildChunk(id, 1, 2, []byte("world")))
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	got, err = b.Add(buildChunk(id, 0, 2, []byte("hello ")))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello world"), got)
+}
+
+func TestChunkBufferIgnoresDuplicateChunk(t *testing.T) {
+	id := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+	b := newChunkBuffer(time.Second, 0)
+
+	_, err := b.Add(buildChunk(id, 0, 2, []byte("hello ")))
+	require.NoError(t, err)
+
+	got, err := b.Add(buildChunk(id, 0, 2, []byte("hello ")))
+	require.NoError(t, err)
+	assert.Nil(t, got, "duplicate chunk must not complete the message")
+
+	got, err = b.Add(buildChunk(id, 1, 2, []byte("world")))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello world"), got)
+}
+
+func TestChunkBufferDropsIncompleteMessageAfterTimeout(t *testing.T) {
+	id := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+	b := newChunkBuffer(20*time.Millisecond, 0)
+
+	_, err := b.Add(buildChunk(id, 0, 2, []byte("hello ")))
+	require.NoError(t, err)
+
+	b.mu.Lock()
+	_, pending := b.pending[string(id[:])]
+	b.mu.Unlock()
+	require.True(t, pending)
+
+	time.Sleep(50 * time.Millisecond)
+	b.purgeExpired()
+
+	b.mu.Lock()
+	_, pending = b.pending[string(id[:])]
+	b.mu.Unlock()
+	assert.False(t, pending, "incomplete message should be dropped after chunk_timeout")
+}
+
+func TestChunkBufferPassesThroughUnchunkedPayload(t *testing.T) {
+	b := newChunkBuffer(time.Second, 0)
+	got, err := b.Add([]byte(`{"version":"1.1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, []byte(`{"version":"1.1"}`), got)
+}
+
+func TestChunkBufferRejectsOversizedMessage(t *testing.T) {
+	id := [8]byte{1, 2, 3, 4, 5, 6, 7, 8}
+	b := newChunkBuffer(time.Second, 4)
+
+	_, err := b.Add(buildChunk(id, 0, 2, []byte("hello")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,36 @@
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io/ioutil"
+)
+
+// decompress reverses any compression applied to a (already dechunked)
+// GELF payload. Graylog clients may send the payload gzip- or
+// zlib-compressed, or as raw JSON; the format is auto-detected from the
+// leading bytes.
+func decompress(data []byte) ([]byte, error) {
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %s", err)
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case len(data) >= 1 && data[0] == 0x78:
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("zlib: %s", err)
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case len(data) >= 1 && data[0] == '{':
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unrecognized GELF payload: not gzip, zlib or JSON")
+	}
+}
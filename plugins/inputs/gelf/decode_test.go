@@ -0,0 +1,55 @@
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func zlibBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestDecompressGzip(t *testing.T) {
+	want := []byte(`{"version":"1.1","host":"h","short_message":"m"}`)
+	got, err := decompress(gzipBytes(t, want))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestDecompressZlib(t *testing.T) {
+	want := []byte(`{"version":"1.1","host":"h","short_message":"m"}`)
+	got, err := decompress(zlibBytes(t, want))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestDecompressRawJSON(t *testing.T) {
+	want := []byte(`{"version":"1.1","host":"h","short_message":"m"}`)
+	got, err := decompress(want)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestDecompressRejectsUnrecognizedPayload(t *testing.T) {
+	_, err := decompress([]byte{0x00, 0x01, 0x02})
+	assert.Error(t, err)
+}
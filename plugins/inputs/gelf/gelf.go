@@ -0,0 +1,347 @@
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+// GELF is a telegraf ServiceInput that listens for GELF (Graylog Extended
+// Log Format) messages on UDP, TCP and HTTP, decodes them, and turns them
+// into telegraf metrics. It is the receiving counterpart to the graylog
+// REST poller: graylog pulls server metrics, GELF accepts log messages
+// applications already emit.
+type GELF struct {
+	UDPAddress  string `toml:"udp_address"`
+	TCPAddress  string `toml:"tcp_address"`
+	HTTPAddress string `toml:"http_address"`
+	HTTPPath    string `toml:"http_path"`
+
+	MeasurementName string   `toml:"measurement_name"`
+	ExtraAsTags     []string `toml:"extra_as_tags"`
+
+	// How long an incomplete chunked UDP message is kept before being
+	// dropped.
+	ChunkTimeout string `toml:"chunk_timeout"`
+	// Upper bound, in bytes, on the reassembled size of a single chunked
+	// message. 0 disables the check.
+	MaxMessageSize int `toml:"max_message_size"`
+
+	acc telegraf.Accumulator
+
+	udpConn      *net.UDPConn
+	tcpListener  net.Listener
+	httpListener net.Listener
+	httpServer   *http.Server
+
+	chunks *chunkBuffer
+
+	closing chan struct{}
+	wg      sync.WaitGroup
+}
+
+var sampleConfig = `
+  ## Listen for GELF messages over UDP. GELF UDP messages larger than the
+  ## MTU may arrive split into chunks; these are reassembled automatically.
+  udp_address = ":12201"
+
+  ## Listen for GELF messages over TCP. TCP messages are null-byte
+  ## delimited and are never chunked.
+  # tcp_address = ":12201"
+
+  ## Listen for a single GELF JSON document per HTTP POST.
+  # http_address = ":12202"
+  # http_path = "/gelf"
+
+  ## Measurement name to use for decoded GELF messages.
+  # measurement_name = "gelf"
+
+  ## GELF "additional fields" (those prefixed with "_") listed here are
+  ## added as tags instead of fields.
+  # extra_as_tags = ["facility"]
+
+  ## How long to wait for all chunks of a chunked UDP message before
+  ## giving up on it.
+  # chunk_timeout = "5s"
+
+  ## Maximum reassembled size, in bytes, of a chunked UDP message.
+  ## 0 disables the limit.
+  # max_message_size = 1048576
+`
+
+func (g *GELF) SampleConfig() string {
+	return sampleConfig
+}
+
+func (g *GELF) Description() string {
+	return "Accept GELF messages over UDP, TCP or HTTP and turn them into metrics"
+}
+
+func (g *GELF) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
+// Start launches the configured listeners. It satisfies
+// telegraf.ServiceInput.
+func (g *GELF) Start(acc telegraf.Accumulator) error {
+	g.acc = acc
+	g.closing = make(chan struct{})
+
+	timeout, err := time.ParseDuration(g.ChunkTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid chunk_timeout %q: %s", g.ChunkTimeout, err)
+	}
+	g.chunks = newChunkBuffer(timeout, g.MaxMessageSize)
+	g.chunks.Start()
+
+	if g.UDPAddress != "" {
+		if err := g.listenUDP(g.UDPAddress); err != nil {
+			return fmt.Errorf("starting GELF UDP listener: %s", err)
+		}
+	}
+	if g.TCPAddress != "" {
+		if err := g.listenTCP(g.TCPAddress); err != nil {
+			return fmt.Errorf("starting GELF TCP listener: %s", err)
+		}
+	}
+	if g.HTTPAddress != "" {
+		if err := g.listenHTTP(g.HTTPAddress, g.HTTPPath); err != nil {
+			return fmt.Errorf("starting GELF HTTP listener: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// Stop shuts down every listener and waits for their goroutines to exit.
+func (g *GELF) Stop() {
+	close(g.closing)
+
+	if g.udpConn != nil {
+		g.udpConn.Close()
+	}
+	if g.tcpListener != nil {
+		g.tcpListener.Close()
+	}
+	if g.httpServer != nil {
+		g.httpServer.Close()
+	}
+	g.chunks.Stop()
+	g.wg.Wait()
+}
+
+func (g *GELF) isClosing() bool {
+	select {
+	case <-g.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+func (g *GELF) listenUDP(addr string) error {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return err
+	}
+	g.udpConn = conn
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		buf := make([]byte, 65507) // max UDP payload size
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				if g.isClosing() {
+					return
+				}
+				log.Printf("E! [inputs.gelf] udp read error: %s", err)
+				continue
+			}
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			g.processChunk(data)
+		}
+	}()
+	return nil
+}
+
+func (g *GELF) listenTCP(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	g.tcpListener = ln
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if g.isClosing() {
+					return
+				}
+				log.Printf("E! [inputs.gelf] tcp accept error: %s", err)
+				continue
+			}
+			g.wg.Add(1)
+			go g.handleTCPConn(conn)
+		}
+	}()
+	return nil
+}
+
+func (g *GELF) handleTCPConn(conn net.Conn) {
+	defer g.wg.Done()
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		data, err := reader.ReadBytes(0)
+		if err != nil {
+			return
+		}
+		// TCP GELF messages are never chunked; go straight to
+		// decompression/parsing.
+		g.processMessage(bytes.TrimSuffix(data, []byte{0}))
+	}
+}
+
+func (g *GELF) listenHTTP(addr, path string) error {
+	if path == "" {
+		path = "/gelf"
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	g.httpListener = ln
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, g.handleHTTP)
+	server := &http.Server{Handler: mux}
+	g.httpServer = server
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		if err := server.Serve(ln); err != nil && err != http.ErrServerClosed {
+			log.Printf("E! [inputs.gelf] http server error: %s", err)
+		}
+	}()
+	return nil
+}
+
+func (g *GELF) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	g.processMessage(body)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// processChunk runs a raw UDP datagram through chunk reassembly before
+// handing a complete message off for decoding.
+func (g *GELF) processChunk(data []byte) {
+	assembled, err := g.chunks.Add(data)
+	if err != nil {
+		log.Printf("E! [inputs.gelf] %s", err)
+		return
+	}
+	if assembled == nil {
+		// Still waiting on more chunks of this message.
+		return
+	}
+	g.processMessage(assembled)
+}
+
+// processMessage decompresses and parses a complete GELF payload and
+// records it as a metric.
+func (g *GELF) processMessage(data []byte) {
+	plain, err := decompress(data)
+	if err != nil {
+		log.Printf("E! [inputs.gelf] %s", err)
+		return
+	}
+
+	var msg gelfMessage
+	if err := json.Unmarshal(plain, &msg); err != nil {
+		log.Printf("E! [inputs.gelf] %s", err)
+		return
+	}
+
+	g.addMetric(&msg)
+}
+
+func (g *GELF) addMetric(msg *gelfMessage) {
+	tags := map[string]string{
+		"host": msg.Host,
+	}
+	fields := map[string]interface{}{
+		"short_message": msg.ShortMessage,
+	}
+	if msg.FullMessage != "" {
+		fields["full_message"] = msg.FullMessage
+	}
+	if msg.HasLevel {
+		tags["level"] = fmt.Sprintf("%d", msg.Level)
+	}
+
+	extraAsTags := make(map[string]bool, len(g.ExtraAsTags))
+	for _, k := range g.ExtraAsTags {
+		extraAsTags[k] = true
+	}
+	for k, v := range msg.Extra {
+		if extraAsTags[k] {
+			tags[k] = fmt.Sprintf("%v", v)
+		} else {
+			fields[k] = v
+		}
+	}
+
+	t := time.Now()
+	if msg.Timestamp != 0 {
+		sec := int64(msg.Timestamp)
+		nsec := int64((msg.Timestamp - float64(sec)) * float64(time.Second))
+		t = time.Unix(sec, nsec)
+	}
+
+	g.acc.AddFields(g.MeasurementName, fields, tags, t)
+}
+
+func init() {
+	inputs.Add("gelf", func() telegraf.Input {
+		return &GELF{
+			MeasurementName: "gelf",
+			HTTPPath:        "/gelf",
+			ChunkTimeout:    "5s",
+			MaxMessageSize:  1024 * 1024,
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package gelf
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGELF() *GELF {
+	return &GELF{
+		MeasurementName: "gelf",
+		HTTPPath:        "/gelf",
+		ChunkTimeout:    "5s",
+		MaxMessageSize:  1024 * 1024,
+	}
+}
+
+func TestGELFUDPListenerDecodesMessage(t *testing.T) {
+	g := newTestGELF()
+	g.UDPAddress = "127.0.0.1:0"
+
+	var acc testutil.Accumulator
+	require.NoError(t, g.Start(&acc))
+	defer g.Stop()
+
+	conn, err := net.Dial("udp", g.udpConn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(`{"version":"1.1","host":"srv1","short_message":"hi","level":6,"_facility":"auth"}`))
+	require.NoError(t, err)
+
+	require.True(t, acc.Wait(1))
+	assert.True(t, acc.HasField("gelf", "short_message"))
+	assert.Equal(t, "srv1", acc.TagValue("gelf", "host"))
+}
+
+func TestGELFTCPListenerDecodesNullDelimitedMessage(t *testing.T) {
+	g := newTestGELF()
+	g.TCPAddress = "127.0.0.1:0"
+
+	var acc testutil.Accumulator
+	require.NoError(t, g.Start(&acc))
+	defer g.Stop()
+
+	conn, err := net.Dial("tcp", g.tcpListener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(`{"version":"1.1","host":"srv1","short_message":"hi"}` + "\x00"))
+	require.NoError(t, err)
+
+	require.True(t, acc.Wait(1))
+	assert.True(t, acc.HasField("gelf", "short_message"))
+}
+
+func TestGELFHTTPListenerDecodesMessage(t *testing.T) {
+	g := newTestGELF()
+	g.HTTPAddress = "127.0.0.1:0"
+
+	var acc testutil.Accumulator
+	require.NoError(t, g.Start(&acc))
+	defer g.Stop()
+
+	url := "http://" + g.httpListener.Addr().String() + g.HTTPPath
+	resp, err := http.Post(url, "application/json", strings.NewReader(`{"version":"1.1","host":"srv1","short_message":"hi"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	require.True(t, acc.Wait(1))
+	assert.True(t, acc.HasField("gelf", "short_message"))
+}
+
+func TestGELFUDPListenerReassemblesChunkedMessage(t *testing.T) {
+	g := newTestGELF()
+	g.UDPAddress = "127.0.0.1:0"
+
+	var acc testutil.Accumulator
+	require.NoError(t, g.Start(&acc))
+	defer g.Stop()
+
+	conn, err := net.Dial("udp", g.udpConn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	payload := []byte(`{"version":"1.1","host":"srv1","short_message":"chunked"}`)
+	mid := len(payload) / 2
+	id := [8]byte{9, 9, 9, 9, 9, 9, 9, 9}
+
+	_, err = conn.Write(buildChunk(id, 1, 2, payload[mid:]))
+	require.NoError(t, err)
+	_, err = conn.Write(buildChunk(id, 0, 2, payload[:mid]))
+	require.NoError(t, err)
+
+	require.True(t, acc.Wait(1))
+	assert.True(t, acc.HasField("gelf", "short_message"))
+}
@@ -0,0 +1,64 @@
+package gelf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// gelfMessage is a single decoded GELF 1.1 document.
+// See http://docs.graylog.org/en/latest/pages/gelf.html#gelf-payload-specification
+type gelfMessage struct {
+	Version      string
+	Host         string
+	ShortMessage string
+	FullMessage  string
+	Timestamp    float64
+	Level        int
+	// HasLevel distinguishes an absent "level" field from an explicit
+	// level of 0 (Emergency), which is both valid and the most severe.
+	HasLevel bool
+
+	// Extra holds the GELF "additional fields": any key prefixed with an
+	// underscore, with the underscore stripped. The reserved "_id" field
+	// is discarded, per spec.
+	Extra map[string]interface{}
+}
+
+func (m *gelfMessage) UnmarshalJSON(data []byte) error {
+	raw := make(map[string]interface{})
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	version, _ := raw["version"].(string)
+	host, _ := raw["host"].(string)
+	shortMessage, _ := raw["short_message"].(string)
+	if version == "" || host == "" || shortMessage == "" {
+		return fmt.Errorf("GELF message missing required field(s): version, host, short_message")
+	}
+	m.Version = version
+	m.Host = host
+	m.ShortMessage = shortMessage
+
+	if fullMessage, ok := raw["full_message"].(string); ok {
+		m.FullMessage = fullMessage
+	}
+	if timestamp, ok := raw["timestamp"].(float64); ok {
+		m.Timestamp = timestamp
+	}
+	if level, ok := raw["level"].(float64); ok {
+		m.Level = int(level)
+		m.HasLevel = true
+	}
+
+	m.Extra = make(map[string]interface{})
+	for k, v := range raw {
+		if !strings.HasPrefix(k, "_") || k == "_id" {
+			continue
+		}
+		m.Extra[strings.TrimPrefix(k, "_")] = v
+	}
+
+	return nil
+}
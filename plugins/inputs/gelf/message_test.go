@@ -0,0 +1,62 @@
+package gelf
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGELFMessageUnmarshalRequiredFields(t *testing.T) {
+	var msg gelfMessage
+	err := json.Unmarshal([]byte(`{"version":"1.1","host":"srv1","short_message":"boom"}`), &msg)
+	require.NoError(t, err)
+	assert.Equal(t, "1.1", msg.Version)
+	assert.Equal(t, "srv1", msg.Host)
+	assert.Equal(t, "boom", msg.ShortMessage)
+}
+
+func TestGELFMessageUnmarshalMissingRequiredField(t *testing.T) {
+	var msg gelfMessage
+	err := json.Unmarshal([]byte(`{"version":"1.1","host":"srv1"}`), &msg)
+	assert.Error(t, err)
+}
+
+func TestGELFMessageUnmarshalExtraFields(t *testing.T) {
+	var msg gelfMessage
+	err := json.Unmarshal([]byte(`{
+		"version":"1.1",
+		"host":"srv1",
+		"short_message":"boom",
+		"timestamp": 1600000000.5,
+		"level": 3,
+		"full_message":"boom: stack trace",
+		"_facility":"auth",
+		"_user_id": 42,
+		"_id": "should-be-discarded"
+	}`), &msg)
+	require.NoError(t, err)
+	assert.Equal(t, 3, msg.Level)
+	assert.True(t, msg.HasLevel)
+	assert.Equal(t, "boom: stack trace", msg.FullMessage)
+	assert.Equal(t, "auth", msg.Extra["facility"])
+	assert.Equal(t, 42.0, msg.Extra["user_id"])
+	_, hasID := msg.Extra["id"]
+	assert.False(t, hasID, "_id is reserved and must be discarded")
+}
+
+func TestGELFMessageUnmarshalLevelZeroIsPresent(t *testing.T) {
+	var msg gelfMessage
+	err := json.Unmarshal([]byte(`{"version":"1.1","host":"srv1","short_message":"boom","level":0}`), &msg)
+	require.NoError(t, err)
+	assert.Equal(t, 0, msg.Level)
+	assert.True(t, msg.HasLevel, "an explicit level of 0 (Emergency) must not be treated as absent")
+}
+
+func TestGELFMessageUnmarshalNoLevel(t *testing.T) {
+	var msg gelfMessage
+	err := json.Unmarshal([]byte(`{"version":"1.1","host":"srv1","short_message":"boom"}`), &msg)
+	require.NoError(t, err)
+	assert.False(t, msg.HasLevel)
+}
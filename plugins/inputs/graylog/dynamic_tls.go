@@ -0,0 +1,218 @@
+package graylog
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// cachedCAPool is the result of the last successful parse of the CA file,
+// plus the file metadata it was parsed from so unchanged files are not
+// re-parsed on every handshake.
+type cachedCAPool struct {
+	pool    *x509.CertPool
+	modTime time.Time
+	size    int64
+}
+
+// cachedKeyPair is the result of the last successful load of the client
+// certificate/key pair, plus the file metadata used to detect changes.
+type cachedKeyPair struct {
+	cert *tls.Certificate
+
+	certModTime time.Time
+	certSize    int64
+	keyModTime  time.Time
+	keySize     int64
+}
+
+// dynamicTLS re-reads the configured CA, certificate and key files from
+// disk so that rotating them in place is picked up without restarting
+// telegraf, in the style of etcd's transport layer. Parsed material is
+// cached and only refreshed when the underlying file's mtime or size has
+// changed, and no more often than reloadInterval. A file that fails to
+// parse (e.g. a half-written rotation) does not invalidate the last-good
+// cache entry.
+type dynamicTLS struct {
+	caFile   string
+	certFile string
+	keyFile  string
+
+	reloadInterval time.Duration
+
+	mu           sync.Mutex
+	ca           cachedCAPool
+	keyPair      cachedKeyPair
+	lastCACheck  time.Time
+	lastKeyCheck time.Time
+}
+
+func newDynamicTLS(caFile, certFile, keyFile string, reloadInterval time.Duration) *dynamicTLS {
+	return &dynamicTLS{
+		caFile:         caFile,
+		certFile:       certFile,
+		keyFile:        keyFile,
+		reloadInterval: reloadInterval,
+	}
+}
+
+// TLSConfig returns a tls.Config whose client certificate is re-read from
+// disk on every handshake (or at most every reloadInterval). If no CA file
+// is configured, normal Go certificate verification applies (system roots,
+// hostname check) exactly as it did before. If a CA file is configured, the
+// default verification is replaced by verifyConnection, which re-loads the
+// CA pool from disk on every handshake and verifies the certificate chain
+// against it, and the server hostname where one was dialed (see
+// verifyConnection).
+func (d *dynamicTLS) TLSConfig() *tls.Config {
+	cfg := &tls.Config{}
+
+	if d.certFile != "" && d.keyFile != "" {
+		cfg.GetClientCertificate = func(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return d.clientCertificate()
+		}
+	}
+
+	if d.caFile != "" {
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = d.verifyConnection
+	}
+
+	return cfg
+}
+
+// dueForCheck reports whether enough time has passed since the last stat
+// of the watched file(s) to check again, and updates *last if so.
+func (d *dynamicTLS) dueForCheck(last *time.Time) bool {
+	if d.reloadInterval > 0 && !last.IsZero() && time.Since(*last) < d.reloadInterval {
+		return false
+	}
+	*last = time.Now()
+	return true
+}
+
+// caPool returns the current trusted CA pool, reloading it from caFile if
+// the file has changed since it was last parsed.
+func (d *dynamicTLS) caPool() (*x509.CertPool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.ca.pool != nil && !d.dueForCheck(&d.lastCACheck) {
+		return d.ca.pool, nil
+	}
+
+	info, err := os.Stat(d.caFile)
+	if err != nil {
+		if d.ca.pool != nil {
+			return d.ca.pool, nil
+		}
+		return nil, err
+	}
+
+	if d.ca.pool != nil && info.ModTime().Equal(d.ca.modTime) && info.Size() == d.ca.size {
+		return d.ca.pool, nil
+	}
+
+	pemBytes, err := ioutil.ReadFile(d.caFile)
+	if err != nil {
+		if d.ca.pool != nil {
+			return d.ca.pool, nil
+		}
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemBytes) {
+		if d.ca.pool != nil {
+			return d.ca.pool, nil
+		}
+		return nil, fmt.Errorf("could not parse any PEM certificates in %q", d.caFile)
+	}
+
+	d.ca = cachedCAPool{pool: pool, modTime: info.ModTime(), size: info.Size()}
+	return d.ca.pool, nil
+}
+
+// clientCertificate returns the current client certificate/key pair,
+// reloading it from certFile/keyFile if either file has changed since it
+// was last loaded.
+func (d *dynamicTLS) clientCertificate() (*tls.Certificate, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.dueForCheck(&d.lastKeyCheck) {
+		return d.keyPair.cert, nil
+	}
+
+	certInfo, err := os.Stat(d.certFile)
+	if err != nil {
+		if d.keyPair.cert != nil {
+			return d.keyPair.cert, nil
+		}
+		return nil, err
+	}
+
+	keyInfo, err := os.Stat(d.keyFile)
+	if err != nil {
+		if d.keyPair.cert != nil {
+			return d.keyPair.cert, nil
+		}
+		return nil, err
+	}
+
+	if d.keyPair.cert != nil &&
+		certInfo.ModTime().Equal(d.keyPair.certModTime) && certInfo.Size() == d.keyPair.certSize &&
+		keyInfo.ModTime().Equal(d.keyPair.keyModTime) && keyInfo.Size() == d.keyPair.keySize {
+		return d.keyPair.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(d.certFile, d.keyFile)
+	if err != nil {
+		if d.keyPair.cert != nil {
+			return d.keyPair.cert, nil
+		}
+		return nil, err
+	}
+
+	d.keyPair = cachedKeyPair{
+		cert:        &cert,
+		certModTime: certInfo.ModTime(),
+		certSize:    certInfo.Size(),
+		keyModTime:  keyInfo.ModTime(),
+		keySize:     keyInfo.Size(),
+	}
+	return d.keyPair.cert, nil
+}
+
+// verifyConnection verifies the server's certificate chain against a
+// freshly loaded CA pool, and the server hostname (taken from
+// cs.ServerName, the SNI name the connection was dialed with) against the
+// leaf certificate. A server dialed by IP address gets no SNI name, so
+// cs.ServerName is empty and only the chain is verified; the IP address is
+// not checked against the certificate.
+func (d *dynamicTLS) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return fmt.Errorf("no server certificate presented")
+	}
+
+	pool, err := d.caPool()
+	if err != nil {
+		return err
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err = cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         pool,
+		Intermediates: intermediates,
+	})
+	return err
+}
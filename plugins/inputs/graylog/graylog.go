@@ -2,6 +2,7 @@ package graylog
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -15,7 +16,6 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
-	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -45,8 +45,12 @@ type GrayLog struct {
 	SSLKey string `toml:"ssl_key"`
 	// Use SSL but skip chain & host verification
 	InsecureSkipVerify bool
+	// How often the SSL files are allowed to be re-checked for changes.
+	// "0s" (the default) re-checks on every handshake.
+	TLSReloadInterval string `toml:"tls_reload_interval"`
 
 	client HTTPClient
+	tls    *dynamicTLS
 }
 
 type HTTPClient interface {
@@ -113,6 +117,11 @@ var sampleConfig = `
   # ssl_key = "/etc/telegraf/key.pem"
   ## Use SSL but skip chain & host verification
   # insecure_skip_verify = false
+  ## SSL/TLS files are watched for changes and reloaded from disk so that
+  ## rotating the Graylog server's CA bundle does not require a restart.
+  ## How often to allow a re-check of the files on disk; defaults to
+  ## checking on every TLS handshake.
+  # tls_reload_interval = "0s"
 `
 
 func (h *GrayLog) SampleConfig() string {
@@ -123,13 +132,46 @@ func (h *GrayLog) Description() string {
 	return "Read flattened metrics from one or more GrayLog HTTP endpoints"
 }
 
+// buildTLSConfig builds the tls.Config used for connections to Graylog. If
+// any of SSLCA, SSLCert or SSLKey is configured, the returned config loads
+// that material from disk on every handshake (bounded by
+// TLSReloadInterval) rather than once at startup, so that rotating the
+// files on disk is picked up without a telegraf restart. InsecureSkipVerify
+// turns off chain and host verification in either case.
+func (h *GrayLog) buildTLSConfig() (*tls.Config, error) {
+	if h.SSLCA == "" && h.SSLCert == "" && h.SSLKey == "" {
+		if h.InsecureSkipVerify {
+			return &tls.Config{InsecureSkipVerify: true}, nil
+		}
+		return nil, nil
+	}
+
+	if h.tls == nil {
+		reloadInterval := h.TLSReloadInterval
+		if reloadInterval == "" {
+			reloadInterval = "0s"
+		}
+		interval, err := time.ParseDuration(reloadInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls_reload_interval %q: %s", reloadInterval, err)
+		}
+		h.tls = newDynamicTLS(h.SSLCA, h.SSLCert, h.SSLKey, interval)
+	}
+
+	cfg := h.tls.TLSConfig()
+	if h.InsecureSkipVerify {
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = nil
+	}
+	return cfg, nil
+}
+
 // Gathers data for all servers.
 func (h *GrayLog) Gather(acc telegraf.Accumulator) error {
 	var wg sync.WaitGroup
 
 	if h.client.HTTPClient() == nil {
-		tlsCfg, err := internal.GetTLSConfig(
-			h.SSLCert, h.SSLKey, h.SSLCA, h.InsecureSkipVerify)
+		tlsCfg, err := h.buildTLSConfig()
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,127 @@
+package graylog
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var graylogMetrics = `
+{
+  "total": 1,
+  "metrics": [
+    {
+      "full_name": "jvm.cl.loaded",
+      "name": "cl.loaded",
+      "type": "GAUGE",
+      "metric": {
+        "value": 1
+      }
+    }
+  ]
+}
+`
+
+func writeCertPEM(t *testing.T, path string, der []byte) {
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// unrelatedCACertDER returns a freshly generated self-signed CA certificate
+// that did not sign the httptest server certificate.
+func unrelatedCACertDER(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "unrelated test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return der
+}
+
+// TestGatherReloadsCAWithoutRestart verifies that, after the CA file on
+// disk is replaced mid-test, the next Gather call trusts the new CA
+// without needing to reconstruct the GrayLog input (i.e. without a
+// telegraf restart).
+func TestGatherReloadsCAWithoutRestart(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, graylogMetrics)
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "graylog-tls-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	caFile := filepath.Join(dir, "ca.pem")
+
+	// Start out trusting some other, unrelated certificate so the first
+	// gather fails chain verification. (Every httptest TLS server uses the
+	// same built-in certificate, so a second httptest server would not do.)
+	writeCertPEM(t, caFile, unrelatedCACertDER(t))
+
+	g := &GrayLog{
+		Servers: []string{ts.URL + "/system/metrics/multiple"},
+		Metrics: []string{"jvm.cl.loaded"},
+		SSLCA:   caFile,
+		client:  &RealHTTPClient{},
+	}
+
+	var acc testutil.Accumulator
+	assert.Error(t, acc.GatherError(g.Gather))
+
+	// Rotate the CA file on disk to the real server's certificate; no
+	// restart, just write the new file in place.
+	writeCertPEM(t, caFile, ts.Certificate().Raw)
+	g.client.HTTPClient().Transport.(interface{ CloseIdleConnections() }).CloseIdleConnections()
+
+	acc = testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(g.Gather))
+	assert.True(t, acc.HasField("jvm.cl.loaded", "value"))
+}
+
+func TestBuildTLSConfigNoSSLConfigured(t *testing.T) {
+	g := &GrayLog{}
+	cfg, err := g.buildTLSConfig()
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestBuildTLSConfigInsecureSkipVerify(t *testing.T) {
+	g := &GrayLog{InsecureSkipVerify: true}
+	cfg, err := g.buildTLSConfig()
+	require.NoError(t, err)
+	assert.True(t, cfg.InsecureSkipVerify)
+	assert.Nil(t, cfg.VerifyConnection)
+}
+
+func TestBuildTLSConfigInvalidReloadInterval(t *testing.T) {
+	g := &GrayLog{SSLCA: "ca.pem", TLSReloadInterval: "not-a-duration"}
+	_, err := g.buildTLSConfig()
+	assert.Error(t, err)
+}